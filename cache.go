@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"github.com/wolfposd/mensabot/scraper"
+)
+
+// fetchCanteenPlan returns the plan for canteen on the day offset days from
+// today, serving it from the store if a fresh-enough entry exists and
+// otherwise scraping it via canteen's configured Scraper.
+func (bot *mensabot) fetchCanteenPlan(canteen canteenConfig, offset int) ([]dish, error) {
+	date := today().AddDate(0, 0, offset)
+
+	if bot.store != nil {
+		if stored, fetchedAt, ok, err := bot.store.GetPlan(canteen.ID, date); err == nil && ok && time.Since(fetchedAt) < bot.cacheTTL {
+			return dishesFromScraper(stored), nil
+		} else if err != nil {
+			log.Warn().Str("component", "fetchCanteenPlan").Str("canteen_id", canteen.ID).Err(err).Msg("failed to read cache")
+		}
+	}
+
+	s, err := scraper.Get(canteen.Scraper)
+	if err != nil {
+		return nil, err
+	}
+	scraped, err := s.FetchDay(canteen.ID, offset)
+	if err != nil {
+		log.Warn().Str("component", "fetchCanteenPlan").Str("canteen_id", canteen.ID).Str("url", canteen.Scraper).Err(err).Msg("scrape failed")
+		return nil, err
+	}
+	bot.health.recordScrape()
+	log.Debug().Str("component", "fetchCanteenPlan").Str("canteen_id", canteen.ID).Int("dish_count", len(scraped)).Msg("scraped plan")
+
+	if bot.store != nil {
+		if err := bot.store.SavePlan(canteen.ID, date, scraped); err != nil {
+			log.Warn().Str("component", "fetchCanteenPlan").Str("canteen_id", canteen.ID).Err(err).Msg("failed to write cache")
+		}
+	}
+
+	return dishesFromScraper(scraped), nil
+}
+
+// runPruneLoop periodically deletes plans older than prunePlansOlderThan
+// from the store, until the process exits.
+func (bot *mensabot) runPruneLoop() {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if bot.store == nil {
+			continue
+		}
+		if err := bot.store.Prune(time.Now().Add(-prunePlansOlderThan)); err != nil {
+			log.Warn().Str("component", "runPruneLoop").Err(err).Msg("failed to prune store")
+		}
+	}
+}
+
+func dishFromScraper(d scraper.Dish) dish {
+	return dish{
+		name:            d.Name,
+		prices:          d.Prices,
+		isVegetarian:    d.IsVegetarian,
+		isVegan:         d.IsVegan,
+		containsBeef:    d.ContainsBeef,
+		containsPork:    d.ContainsPork,
+		containsFish:    d.ContainsFish,
+		containsChicken: d.ContainsChicken,
+		lactoseFree:     d.LactoseFree,
+	}
+}
+
+func dishesFromScraper(dishes []scraper.Dish) []dish {
+	out := make([]dish, len(dishes))
+	for i, d := range dishes {
+		out[i] = dishFromScraper(d)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// SlashPrefix is the Mattermost-style slash command that is equivalent to
+// mentioning the bot, e.g. "/mensa today" instead of "@mensabot today".
+const SlashPrefix = "/mensa"
+
+// Command is a single sub-command the bot understands, invoked either via
+// "@<mention> <name> <args...>" or "/mensa <name> <args...>".
+type Command interface {
+	// Name is the canonical, lower-case name of the command.
+	Name() string
+	// Aliases are additional lower-case names (German/English synonyms, ...)
+	// that also trigger the command.
+	Aliases() []string
+	// Usage is a short one-line description shown in the help text.
+	Usage() string
+	// Run executes the command for the given post. args are the tokens
+	// following the command name (may be empty).
+	Run(bot *mensabot, post *model.Post, args []string)
+}
+
+// commandRegistry holds all commands known to the bot, in registration order.
+var commandRegistry []Command
+
+// registerCommand adds cmd to the registry. It is meant to be called from
+// package-level init() functions of the individual command implementations.
+func registerCommand(cmd Command) {
+	commandRegistry = append(commandRegistry, cmd)
+}
+
+// lookupCommand returns the Command matching name (case-insensitively,
+// against both Name() and Aliases()), or nil if there is no match.
+func lookupCommand(name string) Command {
+	name = strings.ToLower(name)
+	for _, cmd := range commandRegistry {
+		if strings.ToLower(cmd.Name()) == name {
+			return cmd
+		}
+		for _, alias := range cmd.Aliases() {
+			if strings.ToLower(alias) == name {
+				return cmd
+			}
+		}
+	}
+	return nil
+}
+
+// parseCommand strips a leading "@<mention>" or "/mensa" trigger from msg and
+// splits the remainder into a command name and its arguments. ok is false if
+// msg does not start with a known trigger.
+func parseCommand(msg string) (name string, args []string, ok bool) {
+	msg = strings.TrimSpace(msg)
+
+	switch {
+	case strings.HasPrefix(msg, CONFIG.MentionName):
+		msg = strings.TrimPrefix(msg, CONFIG.MentionName)
+	case strings.HasPrefix(msg, SlashPrefix):
+		msg = strings.TrimPrefix(msg, SlashPrefix)
+	default:
+		return "", nil, false
+	}
+
+	fields := strings.Fields(msg)
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+
+	return fields[0], fields[1:], true
+}
+
+// parseBareCommand splits msg into a command name and its arguments without
+// requiring a leading trigger. Used only for channelDebug, see handleCommand.
+func parseBareCommand(msg string) (name string, args []string, ok bool) {
+	fields := strings.Fields(msg)
+	if len(fields) == 0 {
+		return "", nil, false
+	}
+	if lookupCommand(fields[0]) == nil {
+		return "", nil, false
+	}
+	return fields[0], fields[1:], true
+}
+
+// writeCommands renders the registered commands as a help table and sends it
+// to channelID, replying to replyToID.
+func (bot *mensabot) writeCommands(channelID string, replyToID string) {
+	cmds := make([]Command, len(commandRegistry))
+	copy(cmds, commandRegistry)
+	sort.Slice(cmds, func(i, j int) bool { return cmds[i].Name() < cmds[j].Name() })
+
+	var buf bytes.Buffer
+	buf.WriteString("**Verfügbare Befehle** _(" + CONFIG.MentionName + " <befehl> oder " + SlashPrefix + " <befehl>)_:\n\n")
+	buf.WriteString("| Befehl | Aliase | Beschreibung |\n")
+	buf.WriteString("| -- | -- | -- |\n")
+	for _, cmd := range cmds {
+		aliases := strings.Join(cmd.Aliases(), ", ")
+		buf.WriteString(fmt.Sprintf("| %s | %s | %s |\n", cmd.Name(), aliases, cmd.Usage()))
+	}
+
+	bot.sendMessage(buf.String(), channelID, replyToID)
+}
+
+// handleCommand dispatches post.Message to the matching registered Command,
+// falling back to a generic "unknown command" reply if nothing matches.
+func (bot *mensabot) handleCommand(post *model.Post) {
+	log.Debug().Str("component", "handleCommand").Str("channel_id", post.ChannelId).Msg(post.Message)
+
+	name, args, ok := parseCommand(post.Message)
+	if !ok && bot.channelDebug != nil && post.ChannelId == bot.channelDebug.Id {
+		// channelDebug is where the bot is tested by hand, so it additionally
+		// accepts a bare command name ("today") without the mention/slash
+		// trigger required everywhere else.
+		name, args, ok = parseBareCommand(post.Message)
+	}
+	if !ok {
+		bot.sendMessage("What does this even mean?!", post.ChannelId, post.Id)
+		return
+	}
+
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		bot.sendMessage("Unbekannter Befehl: `"+name+"`. Nutze `help` für eine Übersicht.", post.ChannelId, post.Id)
+		return
+	}
+
+	cmd.Run(bot, post, args)
+}
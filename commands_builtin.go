@@ -0,0 +1,88 @@
+package main
+
+import (
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func init() {
+	registerCommand(aliveCommand{})
+	registerCommand(todayCommand{})
+	registerCommand(tomorrowCommand{})
+	registerCommand(legendCommand{})
+	registerCommand(helpCommand{})
+}
+
+type aliveCommand struct{}
+
+func (aliveCommand) Name() string      { return "alive" }
+func (aliveCommand) Aliases() []string { return []string{"running", "up"} }
+func (aliveCommand) Usage() string     { return "Prüft, ob der Bot erreichbar ist" }
+
+func (aliveCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	bot.sendMessage("Yes I'm up and running!", post.ChannelId, post.Id)
+}
+
+type todayCommand struct{}
+
+func (todayCommand) Name() string      { return "today" }
+func (todayCommand) Aliases() []string { return []string{"heute"} }
+func (todayCommand) Usage() string {
+	return "Zeigt den heutigen Speiseplan, optional für eine andere Mensa"
+}
+
+func (todayCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	canteen, _ := bot.resolveCanteen(post, args)
+	dishes, err := bot.fetchCanteenPlan(canteen, 0)
+	if err != nil {
+		bot.sendMessage("Konnte den Speiseplan für `"+canteen.Name+"` nicht laden: "+err.Error(), post.ChannelId, post.Id)
+		return
+	}
+	bot.writeDishes(dishes, "**Heute gibt es ("+canteen.Name+"):**", post.ChannelId, post.Id)
+}
+
+type tomorrowCommand struct{}
+
+func (tomorrowCommand) Name() string      { return "tomorrow" }
+func (tomorrowCommand) Aliases() []string { return []string{"morgen"} }
+func (tomorrowCommand) Usage() string {
+	return "Zeigt den morgigen Speiseplan, optional für eine andere Mensa"
+}
+
+func (tomorrowCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	canteen, _ := bot.resolveCanteen(post, args)
+	dishes, err := bot.fetchCanteenPlan(canteen, 1)
+	if err != nil {
+		bot.sendMessage("Konnte den Speiseplan für `"+canteen.Name+"` nicht laden: "+err.Error(), post.ChannelId, post.Id)
+		return
+	}
+	bot.writeDishes(dishes, "**Morgen gibt es ("+canteen.Name+"):**", post.ChannelId, post.Id)
+}
+
+// today returns the current date truncated to midnight, used as the cache
+// key for "today"/"tomorrow"/"week"/"last <weekday>".
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
+
+type legendCommand struct{}
+
+func (legendCommand) Name() string      { return "legend" }
+func (legendCommand) Aliases() []string { return []string{"legende"} }
+func (legendCommand) Usage() string     { return "Erklärt die verwendeten Symbole" }
+
+func (legendCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	bot.writeLegend(post.ChannelId, post.Id)
+}
+
+type helpCommand struct{}
+
+func (helpCommand) Name() string      { return "help" }
+func (helpCommand) Aliases() []string { return []string{"commands", "hilfe"} }
+func (helpCommand) Usage() string     { return "Listet alle verfügbaren Befehle auf" }
+
+func (helpCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	bot.writeCommands(post.ChannelId, post.Id)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func init() {
+	registerCommand(weekCommand{})
+	registerCommand(lastCommand{})
+	registerCommand(historyCommand{})
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"montag": time.Monday, "monday": time.Monday, "mon": time.Monday,
+	"dienstag": time.Tuesday, "tuesday": time.Tuesday, "tue": time.Tuesday,
+	"mittwoch": time.Wednesday, "wednesday": time.Wednesday, "wed": time.Wednesday,
+	"donnerstag": time.Thursday, "thursday": time.Thursday, "thu": time.Thursday,
+	"freitag": time.Friday, "friday": time.Friday, "fri": time.Friday,
+	"samstag": time.Saturday, "saturday": time.Saturday, "sat": time.Saturday,
+	"sonntag": time.Sunday, "sunday": time.Sunday, "sun": time.Sunday,
+}
+
+// weekCommand shows every plan cached so far for Monday to Friday of the
+// current week. Unlike
+// today/tomorrow it never scrapes, since the Studierendenwerk site only
+// publishes today's and tomorrow's menu - the rest of the week fills in as
+// the bot runs.
+type weekCommand struct{}
+
+func (weekCommand) Name() string      { return "week" }
+func (weekCommand) Aliases() []string { return []string{"woche"} }
+func (weekCommand) Usage() string {
+	return "Zeigt die bisher bekannten Gerichte dieser Woche, optional für eine andere Mensa"
+}
+
+func (weekCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	if bot.store == nil {
+		bot.sendMessage("Der Verlauf ist nicht verfügbar.", post.ChannelId, post.Id)
+		return
+	}
+	canteen, _ := bot.resolveCanteen(post, args)
+
+	monday := mostRecentWeekday(today(), time.Monday)
+
+	var buf bytes.Buffer
+	buf.WriteString("**Diese Woche (" + canteen.Name + "):**\n\n")
+	found := false
+	for i := 0; i < 5; i++ {
+		date := monday.AddDate(0, 0, i)
+		stored, _, ok, err := bot.store.GetPlan(canteen.ID, date)
+		if err != nil || !ok {
+			continue
+		}
+		found = true
+		buf.WriteString(fmt.Sprintf("**%s (%s):**\n\n", date.Weekday(), date.Format("02.01.")))
+		buf.WriteString("| Essen | Features | Preise |\n")
+		buf.WriteString("| -- | -- | -- |\n")
+		for _, d := range dishesFromScraper(stored) {
+			buf.WriteString(d.String() + "\n")
+		}
+		buf.WriteString("\n")
+	}
+
+	if !found {
+		buf.WriteString("_Noch keine Gerichte für diese Woche bekannt._")
+	}
+
+	bot.sendMessage(buf.String(), post.ChannelId, post.Id)
+}
+
+// lastCommand shows the cached plan of the most recent past occurrence of a
+// given weekday, e.g. "last friday".
+type lastCommand struct{}
+
+func (lastCommand) Name() string      { return "last" }
+func (lastCommand) Aliases() []string { return []string{"letzter", "letzten"} }
+func (lastCommand) Usage() string     { return "z.B. `last friday` zeigt den Plan des letzten Freitags" }
+
+func (lastCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	if bot.store == nil {
+		bot.sendMessage("Der Verlauf ist nicht verfügbar.", post.ChannelId, post.Id)
+		return
+	}
+	if len(args) == 0 {
+		bot.sendMessage("Nutze z.B. `last friday`.", post.ChannelId, post.Id)
+		return
+	}
+
+	weekday, ok := weekdayNames[strings.ToLower(args[0])]
+	if !ok {
+		bot.sendMessage("Unbekannter Wochentag: `"+args[0]+"`", post.ChannelId, post.Id)
+		return
+	}
+
+	canteen, _ := bot.resolveCanteen(post, nil)
+	date := mostRecentWeekday(today().AddDate(0, 0, -1), weekday)
+	dishes, _, found, err := bot.store.GetPlan(canteen.ID, date)
+	if err != nil {
+		bot.sendMessage("Fehler beim Lesen des Verlaufs: "+err.Error(), post.ChannelId, post.Id)
+		return
+	}
+	if !found {
+		bot.sendMessage(fmt.Sprintf("Kein gespeicherter Plan für %s (%s).", weekday, date.Format("02.01.2006")), post.ChannelId, post.Id)
+		return
+	}
+
+	bot.writeDishes(dishesFromScraper(dishes), fmt.Sprintf("**%s, %s gab es (%s):**", weekday, date.Format("02.01.2006"), canteen.Name), post.ChannelId, post.Id)
+}
+
+// historyCommand searches every cached plan for dishes matching a name.
+type historyCommand struct{}
+
+func (historyCommand) Name() string      { return "history" }
+func (historyCommand) Aliases() []string { return []string{"verlauf"} }
+func (historyCommand) Usage() string     { return "z.B. `history schnitzel` sucht vergangene Gerichte" }
+
+func (historyCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	if bot.store == nil {
+		bot.sendMessage("Der Verlauf ist nicht verfügbar.", post.ChannelId, post.Id)
+		return
+	}
+	if len(args) == 0 {
+		bot.sendMessage("Nutze z.B. `history schnitzel`.", post.ChannelId, post.Id)
+		return
+	}
+
+	query := strings.Join(args, " ")
+	occurrences, err := bot.store.SearchDish(query)
+	if err != nil {
+		bot.sendMessage("Fehler bei der Suche: "+err.Error(), post.ChannelId, post.Id)
+		return
+	}
+	if len(occurrences) == 0 {
+		bot.sendMessage(fmt.Sprintf("Keine Treffer für `%s` im Verlauf.", query), post.ChannelId, post.Id)
+		return
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(fmt.Sprintf("**Treffer für `%s`:**\n\n", query))
+	buf.WriteString("| Datum | Essen | Preise |\n")
+	buf.WriteString("| -- | -- | -- |\n")
+	for _, o := range occurrences {
+		d := dishFromScraper(o.Dish)
+		buf.WriteString(fmt.Sprintf("| %s | %s | %s // %s // %s |\n", o.Date.Format("02.01.2006"), d.name, d.prices[0], d.prices[1], d.prices[2]))
+	}
+
+	bot.sendMessage(buf.String(), post.ChannelId, post.Id)
+}
+
+// mostRecentWeekday returns the most recent date on or before from that
+// falls on weekday.
+func mostRecentWeekday(from time.Time, weekday time.Weekday) time.Time {
+	offset := int(from.Weekday()-weekday+7) % 7
+	return from.AddDate(0, 0, -offset)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+func init() {
+	registerCommand(subscribeCommand{})
+	registerCommand(unsubscribeCommand{})
+	registerCommand(nextCommand{})
+}
+
+// subscriptionFilterAliases maps what a user types to the canonical filter
+// name stored in the subscriptions table and understood by filterDishes.
+var subscriptionFilterAliases = map[string]string{
+	"all":            "all",
+	"vegan":          "vegan",
+	"vegetarian":     "vegetarian",
+	"favorites":      "favorites",
+	"favorites-only": "favorites",
+	"favorite":       "favorites",
+}
+
+type subscribeCommand struct{}
+
+func (subscribeCommand) Name() string      { return "subscribe" }
+func (subscribeCommand) Aliases() []string { return []string{"abonnieren"} }
+func (subscribeCommand) Usage() string {
+	return "z.B. `subscribe vegan` oder `subscribe favorites-only` für eine tägliche DM"
+}
+
+func (subscribeCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	if bot.store == nil {
+		bot.sendMessage("Abonnements sind nicht verfügbar.", post.ChannelId, post.Id)
+		return
+	}
+
+	filterArg := "all"
+	if len(args) > 0 {
+		filterArg = strings.ToLower(args[0])
+	}
+
+	filter, ok := subscriptionFilterAliases[filterArg]
+	if !ok {
+		bot.sendMessage("Unbekannter Filter: `"+filterArg+"`. Verfügbar: all, vegan, vegetarian, favorites-only.", post.ChannelId, post.Id)
+		return
+	}
+
+	if err := bot.store.SaveSubscription(post.UserId, filter); err != nil {
+		bot.sendMessage("Konnte Abonnement nicht speichern: "+err.Error(), post.ChannelId, post.Id)
+		return
+	}
+
+	bot.sendMessage("Du bekommst ab jetzt jeden Morgen eine DM mit Filter `"+filter+"`.", post.ChannelId, post.Id)
+}
+
+type unsubscribeCommand struct{}
+
+func (unsubscribeCommand) Name() string      { return "unsubscribe" }
+func (unsubscribeCommand) Aliases() []string { return []string{"abbestellen"} }
+func (unsubscribeCommand) Usage() string     { return "Beendet die tägliche DM" }
+
+func (unsubscribeCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	if bot.store == nil {
+		bot.sendMessage("Abonnements sind nicht verfügbar.", post.ChannelId, post.Id)
+		return
+	}
+
+	if err := bot.store.RemoveSubscription(post.UserId); err != nil {
+		bot.sendMessage("Konnte Abonnement nicht entfernen: "+err.Error(), post.ChannelId, post.Id)
+		return
+	}
+
+	bot.sendMessage("Du bekommst keine tägliche DM mehr.", post.ChannelId, post.Id)
+}
+
+type nextCommand struct{}
+
+func (nextCommand) Name() string      { return "next" }
+func (nextCommand) Aliases() []string { return []string{"naechster"} }
+func (nextCommand) Usage() string     { return "Zeigt, wann der nächste automatische Post fällig ist" }
+
+func (nextCommand) Run(bot *mensabot, post *model.Post, args []string) {
+	if bot.scheduler == nil {
+		bot.sendMessage("Es ist kein automatischer Post konfiguriert.", post.ChannelId, post.Id)
+		return
+	}
+
+	next := bot.scheduler.NextRun()
+	if next.IsZero() {
+		bot.sendMessage("Der nächste Post wird gerade berechnet, versuch es gleich nochmal.", post.ChannelId, post.Id)
+		return
+	}
+
+	bot.sendMessage("Der nächste automatische Post ist am "+next.Format("Mon, 02.01.2006 15:04 MST")+".", post.ChannelId, post.Id)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// healthState tracks the bot's liveness signals so they can be reported
+// over /health and checked by the heartbeat.
+type healthState struct {
+	mu             sync.Mutex
+	lastEventAt    time.Time
+	backoff        time.Duration
+	successScrapes int64
+	// warned is set once runHeartbeat has sent a stale-connection warning,
+	// so a prolonged outage produces a single alert instead of one per tick.
+	// recordEvent clears it once events are flowing again.
+	warned bool
+}
+
+func newHealthState() *healthState {
+	return &healthState{}
+}
+
+func (h *healthState) recordEvent() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.lastEventAt = time.Now()
+	h.warned = false
+}
+
+func (h *healthState) recordScrape() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.successScrapes++
+}
+
+func (h *healthState) setBackoff(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.backoff = d
+}
+
+func (h *healthState) snapshot() (lastEventAt time.Time, backoff time.Duration, successScrapes int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.lastEventAt, h.backoff, h.successScrapes
+}
+
+// checkStale reports whether lastEventAt is older than staleAfter and this is
+// the first check to notice it since the last recordEvent, latching warned so
+// subsequent calls during the same outage report false.
+func (h *healthState) checkStale(staleAfter time.Duration) (since time.Duration, stale bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.lastEventAt.IsZero() {
+		return 0, false
+	}
+	since = time.Since(h.lastEventAt)
+	if since <= staleAfter || h.warned {
+		return since, false
+	}
+	h.warned = true
+	return since, true
+}
+
+// serveHealth exposes the bot's health as JSON on addr until the process
+// exits or listening fails.
+func (bot *mensabot) serveHealth(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", bot.handleHealth)
+
+	log.Info().Str("component", "serveHealth").Str("addr", addr).Msg("listening for /health")
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Error().Str("component", "serveHealth").Err(err).Msg("failed to serve /health")
+	}
+}
+
+func (bot *mensabot) handleHealth(w http.ResponseWriter, r *http.Request) {
+	lastEventAt, backoff, successScrapes := bot.health.snapshot()
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"last_event_at":%q,"backoff_seconds":%.1f,"successful_scrapes":%d}`+"\n",
+		lastEventAt.Format(time.RFC3339), backoff.Seconds(), successScrapes)
+}
+
+// runHeartbeat periodically checks whether a websocket event has been seen
+// recently and, if not, warns channelDebug - a dropped connection the
+// reconnect loop hasn't yet recovered from is otherwise silent.
+func (bot *mensabot) runHeartbeat(checkInterval, staleAfter time.Duration) {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if since, stale := bot.health.checkStale(staleAfter); stale {
+			bot.sendMessage(fmt.Sprintf("_:warning: Keine Websocket-Events seit %s gesehen._", since.Round(time.Second)), bot.channelDebug.Id, "")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/mattermost/mattermost-server/model"
+)
+
+// log is the bot's structured logger, configured by initLogger from
+// config.LogLevel/LogJSON once the config file has been read.
+var log zerolog.Logger
+
+// initLogger sets up the package-level logger. level is parsed via
+// zerolog.ParseLevel (empty defaults to "info"); jsonOutput switches between
+// machine-readable JSON (for shipping to ELK) and a human-readable console
+// writer (for local development).
+func initLogger(level string, jsonOutput bool) {
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if level == "" || err != nil {
+		lvl = zerolog.InfoLevel
+	}
+
+	var writer = os.Stdout
+	log = zerolog.New(writer).Level(lvl).With().Timestamp().Logger()
+	if !jsonOutput {
+		log = log.Output(zerolog.ConsoleWriter{Out: writer})
+	}
+}
+
+// logAppError logs a Mattermost *model.AppError at Error level with its
+// structured fields, tagged with the component that received it. A nil err
+// is a no-op, so callers can pass resp.Error without checking first.
+func logAppError(component string, err *model.AppError) {
+	if err == nil {
+		return
+	}
+	log.Error().
+		Str("component", component).
+		Str("err_id", err.Id).
+		Str("err_detail", err.DetailedError).
+		Msg(err.Message)
+}
@@ -3,24 +3,34 @@ package main
 import (
 	"bytes"
 	"fmt"
-	"net/http"
 	"os"
 	"os/signal"
-	"regexp"
 	"strings"
+	"time"
 
 	"github.com/burntsushi/toml"
 	"github.com/mattermost/mattermost-server/model"
-	"github.com/yhat/scrape"
-	"golang.org/x/net/html"
-	"golang.org/x/net/html/atom"
+
+	"github.com/wolfposd/mensabot/scheduler"
+	"github.com/wolfposd/mensabot/scraper"
+	"github.com/wolfposd/mensabot/store"
 )
 
 const (
 	VERSION = "v0.1"
 
-	CANTEEN_URL_TODAY    = "http://speiseplan.studierendenwerk-hamburg.de/de/580/2018/0/"
-	CANTEEN_URL_TOMORROW = "http://speiseplan.studierendenwerk-hamburg.de/de/580/2018/99/"
+	DefaultDBPath          = "mensabot.db"
+	DefaultCacheTTLMinutes = 60
+	prunePlansOlderThan    = 90 * 24 * time.Hour
+	pruneInterval          = 24 * time.Hour
+
+	// legacyCanteenID/legacyCanteenName are used when no [[Canteens]] are
+	// configured, so existing deployments keep working unchanged.
+	legacyCanteenID   = "580"
+	legacyCanteenName = "hamburg"
+
+	DefaultHeartbeatStaleMinutes = 15
+	heartbeatCheckInterval       = 1 * time.Minute
 )
 
 type config struct {
@@ -37,6 +47,55 @@ type config struct {
 	ChannelNameProduction string
 
 	Favorites []string
+
+	// DBPath is where the sqlite cache/history database is stored.
+	// Defaults to DefaultDBPath when empty.
+	DBPath string
+	// CacheTTLMinutes is how long a scraped plan is served from cache
+	// before fetchCanteenPlan re-fetches it. Defaults to
+	// DefaultCacheTTLMinutes when zero.
+	CacheTTLMinutes int
+
+	// Canteens lists every canteen the bot can serve. If empty, a single
+	// canteen using the legacy Studierendenwerk Hamburg scraper is assumed.
+	Canteens []canteenConfig
+
+	// PostAt, if set, enables the daily scheduler: "today"'s plan is posted
+	// automatically at this time ("HH:MM") into every canteen's Channel,
+	// and to every subscribed user as a filtered DM.
+	PostAt string
+	// PostWeekdays restricts which days PostAt fires on, e.g.
+	// ["Mon", "Tue", "Wed", "Thu", "Fri"]. Defaults to Monday-Friday.
+	PostWeekdays []string
+	// PostHolidays lists dates PostAt skips even on an enabled weekday, as
+	// "YYYY-MM-DD", e.g. ["2026-12-25", "2026-01-01"].
+	PostHolidays []string
+	// Timezone is the IANA zone PostAt is interpreted in. Defaults to UTC.
+	Timezone string
+
+	// HealthAddr, if set (e.g. ":8080"), serves a /health JSON endpoint
+	// reporting the last websocket event, current reconnect backoff, and
+	// successful scrape count.
+	HealthAddr string
+	// HeartbeatStaleMinutes is how long without a websocket event before
+	// the heartbeat warns channelDebug. Defaults to
+	// DefaultHeartbeatStaleMinutes when zero.
+	HeartbeatStaleMinutes int
+
+	// LogLevel is one of zerolog's level names (debug, info, warn, error, ...).
+	// Defaults to "info" when empty.
+	LogLevel string
+	// LogJSON switches log output to JSON lines, for shipping to ELK.
+	// Defaults to human-readable console output.
+	LogJSON bool
+}
+
+// canteenConfig is one [[Canteens]] entry in the TOML config.
+type canteenConfig struct {
+	ID      string // scraper-specific canteen identifier, e.g. "580"
+	Name    string // short name users type, e.g. "mensa-berliner-tor"
+	Scraper string // registry key, see package scraper
+	Channel string // channel name that is subscribed to this canteen by default
 }
 
 var CONFIG config
@@ -62,6 +121,20 @@ type mensabot struct {
 
 	channelDebug      *model.Channel
 	channelProduction *model.Channel
+
+	store    store.Store
+	cacheTTL time.Duration
+
+	canteens []canteenConfig
+	// channelCanteen maps a channel ID to the canteen it is subscribed to
+	// by default, built once from canteenConfig.Channel at startup.
+	channelCanteen map[string]canteenConfig
+	// canteenChannel maps a canteen's Name to its resolved Channel, so the
+	// scheduler can post without re-resolving it on every run.
+	canteenChannel map[string]*model.Channel
+
+	scheduler *scheduler.Scheduler
+	health    *healthState
 }
 
 func (d dish) isFavorite() bool {
@@ -105,81 +178,35 @@ func (d dish) String() string {
 	return buf.String()
 }
 
-func trimNodeName(name string) (trimmed string) {
-	trimmed = strings.Trim(name, " \t\n")
-	trimmed = strings.Replace(trimmed, "( ", "(", -1)
-	trimmed = strings.Replace(trimmed, " )", ")", -1)
-	trimmed = strings.Replace(trimmed, " ,", ",", -1)
-	trimmed = strings.Replace(trimmed, "  ", " ", -1)
-
-	return
-}
-
-func dishFromNode(node *html.Node) dish {
-	name := trimNodeName(scrape.Text(node))
-
-	var prices [3]string
-	var isVegetarian bool
-	var isVegan bool
-	var containsBeef bool
-	var containsPork bool
-	var containsFish bool
-	var containsChicken bool
-	var lactoseFree bool
-
-	priceNodes := scrape.FindAll(node.Parent, scrape.ByClass("price"))
-	imgNodes := scrape.FindAll(node, scrape.ByTag(atom.Img))
-
-	for i, price := range priceNodes {
-		prices[i] = strings.Replace(scrape.Text(price), "\xc2\xa0", "", -1)
-	}
-
-	for _, img := range imgNodes {
-		switch strings.ToLower(scrape.Attr(img, "title")) {
-		case "vegetarisch":
-			isVegetarian = true
-		case "vegan":
-			isVegan = true
-		case "mit rind":
-			containsBeef = true
-		case "mit schwein":
-			containsPork = true
-		case "mit fisch":
-			containsFish = true
-		case "mit geflügel":
-			containsChicken = true
-		case "laktosefrei":
-			lactoseFree = true
-		}
-	}
+// newMensaBotFromConfig builds a mensabot from cfg and connects it to
+// Mattermost. Transient connection problems are retried forever by
+// retryForever; only a failure with no useful retry (the store failing to
+// open, a malformed scheduler config) is returned as an error, leaving main
+// to decide whether that is fatal.
+func newMensaBotFromConfig(cfg *config) (bot *mensabot, err error) {
+	log.Info().Str("component", "newMensaBotFromConfig").Str("url", cfg.MattermostApiURL).Msg("connecting to mattermost")
+	client := model.NewAPIv4Client(cfg.MattermostApiURL)
 
-	return dish{name, prices, isVegetarian || isVegan, isVegan, containsBeef, containsPork, containsFish, containsChicken, lactoseFree}
-}
+	bot = &mensabot{client: client}
 
-func getCanteenPlan(url string) (dishes []dish) {
-	resp, err := http.Get(url)
-	if err != nil {
-		panic(err)
+	dbPath := cfg.DBPath
+	if dbPath == "" {
+		dbPath = DefaultDBPath
 	}
-	root, err := html.Parse(resp.Body)
-	if err != nil {
-		panic(err)
+	ttlMinutes := cfg.CacheTTLMinutes
+	if ttlMinutes == 0 {
+		ttlMinutes = DefaultCacheTTLMinutes
 	}
+	bot.cacheTTL = time.Duration(ttlMinutes) * time.Minute
+	bot.health = newHealthState()
 
-	dishNodes := scrape.FindAll(root, scrape.ByClass("dish-description"))
-
-	for _, dn := range dishNodes {
-		dishes = append(dishes, dishFromNode(dn))
+	s, err := store.NewSQLiteStore(dbPath)
+	if err != nil {
+		log.Error().Str("component", "newMensaBotFromConfig").Str("db_path", dbPath).Err(err).Msg("failed to open store")
+		return nil, err
 	}
-
-	return
-}
-
-func newMensaBotFromConfig(cfg *config) (bot *mensabot) {
-	println("[newMensaBotFromConfig] Connecting to " + cfg.MattermostApiURL)
-	client := model.NewAPIv4Client(cfg.MattermostApiURL)
-
-	bot = &mensabot{client: client}
+	bot.store = s
+	go bot.runPruneLoop()
 
 	bot.setupGracefulShutdown()
 	bot.ensureServerIsRunning()
@@ -187,18 +214,92 @@ func newMensaBotFromConfig(cfg *config) (bot *mensabot) {
 	bot.setTeam(cfg.TeamName)
 
 	// WebSocket client needs the AuthToken from bot::loginAsBotUser
-	if wsClient, err := model.NewWebSocketClient4(cfg.MattermostWsURL, client.AuthToken); err != nil {
-		println("[newMensaBotFromConfig] Failed to connect to the web socket")
-		printError(err)
-		panic(err)
-	} else {
+	retryForever("connectWebSocket", func() error {
+		wsClient, err := model.NewWebSocketClient4(cfg.MattermostWsURL, client.AuthToken)
+		if err != nil {
+			return err
+		}
 		bot.wsClient = wsClient
-	}
+		return nil
+	})
 
 	bot.channelDebug = bot.getChannel(cfg.ChannelNameDebug)
 	bot.channelProduction = bot.getChannel(cfg.ChannelNameProduction)
 
-	return
+	bot.canteens = cfg.Canteens
+	if len(bot.canteens) == 0 {
+		bot.canteens = []canteenConfig{{
+			ID:      legacyCanteenID,
+			Name:    legacyCanteenName,
+			Scraper: scraper.HamburgScraperName,
+			Channel: cfg.ChannelNameProduction,
+		}}
+	}
+	bot.channelCanteen = make(map[string]canteenConfig)
+	bot.canteenChannel = make(map[string]*model.Channel)
+	for _, c := range bot.canteens {
+		if c.Channel == "" {
+			continue
+		}
+		ch := bot.getChannel(c.Channel)
+		bot.channelCanteen[ch.Id] = c
+		bot.canteenChannel[c.Name] = ch
+	}
+
+	if cfg.PostAt != "" {
+		sched, err := scheduler.New(scheduler.Config{
+			PostAt:   cfg.PostAt,
+			Weekdays: cfg.PostWeekdays,
+			Timezone: cfg.Timezone,
+			Holidays: cfg.PostHolidays,
+		}, bot.postDailyPlans)
+		if err != nil {
+			log.Error().Str("component", "newMensaBotFromConfig").Err(err).Msg("failed to configure scheduler")
+			return nil, err
+		}
+		bot.scheduler = sched
+		bot.scheduler.Start()
+	}
+
+	if cfg.HealthAddr != "" {
+		go bot.serveHealth(cfg.HealthAddr)
+	}
+
+	staleMinutes := cfg.HeartbeatStaleMinutes
+	if staleMinutes == 0 {
+		staleMinutes = DefaultHeartbeatStaleMinutes
+	}
+	go bot.runHeartbeat(heartbeatCheckInterval, time.Duration(staleMinutes)*time.Minute)
+
+	return bot, nil
+}
+
+// resolveCanteen picks which canteen a command should act on: an explicit
+// name/ID given as the first argument takes priority, then the canteen the
+// invoking channel is subscribed to, then the first configured canteen. It
+// returns the remaining arguments with any consumed canteen name stripped.
+func (bot *mensabot) resolveCanteen(post *model.Post, args []string) (canteenConfig, []string) {
+	if len(args) > 0 {
+		if c, ok := bot.findCanteen(args[0]); ok {
+			return c, args[1:]
+		}
+	}
+	if c, ok := bot.channelCanteen[post.ChannelId]; ok {
+		return c, args
+	}
+	return bot.canteens[0], args
+}
+
+// findCanteen looks up a canteen by its configured Name or ID, case
+// insensitively.
+func (bot *mensabot) findCanteen(key string) (canteenConfig, bool) {
+	key = strings.ToLower(key)
+	for _, c := range bot.canteens {
+		if strings.ToLower(c.Name) == key || strings.ToLower(c.ID) == key {
+			return c, true
+		}
+	}
+	return canteenConfig{}, false
 }
 
 func (bot *mensabot) setupGracefulShutdown() {
@@ -217,47 +318,52 @@ func (bot *mensabot) setupGracefulShutdown() {
 }
 
 func (bot *mensabot) ensureServerIsRunning() {
-	if props, resp := bot.client.GetOldClientConfig(""); resp.Error != nil {
-		println("There was a problem pinging the Mattermost server.  Are you sure it's running?")
-		printError(resp.Error)
-		os.Exit(1)
-	} else {
-		println("[bot::ensureServerIsRunning] Server detected and is running version " + props["Version"])
-	}
+	retryForever("ensureServerIsRunning", func() error {
+		props, resp := bot.client.GetOldClientConfig("")
+		if resp.Error != nil {
+			return resp.Error
+		}
+		log.Info().Str("component", "ensureServerIsRunning").Str("version", props["Version"]).Msg("server detected")
+		return nil
+	})
 }
 
 func (bot *mensabot) loginAsBotUser(email string, password string) {
-	if user, resp := bot.client.Login(email, password); resp.Error != nil {
-		println("There was a problem logging into the Mattermost server.")
-		printError(resp.Error)
-		panic(resp.Error)
-	} else {
-		println("[bot::loginAsBotUser] Logged in as user '" + email + "': " + user.Id)
+	retryForever("loginAsBotUser", func() error {
+		user, resp := bot.client.Login(email, password)
+		if resp.Error != nil {
+			return resp.Error
+		}
+		log.Info().Str("component", "loginAsBotUser").Str("email", email).Msg("logged in")
 		bot.user = user
-	}
+		return nil
+	})
 }
 
 func (bot *mensabot) setTeam(teamName string) {
-	if team, resp := bot.client.GetTeamByName(teamName, ""); resp.Error != nil {
-		println("We failed to get the initial load")
-		println("or we do not appear to be a member of the team '" + teamName + "'")
-		printError(resp.Error)
-		panic(resp.Error)
-	} else {
-		println("[bot::setTeam] Got team with name '" + teamName + "`: " + team.Id)
+	retryForever("setTeam", func() error {
+		team, resp := bot.client.GetTeamByName(teamName, "")
+		if resp.Error != nil {
+			return resp.Error
+		}
+		log.Info().Str("component", "setTeam").Str("team", teamName).Msg("team resolved")
 		bot.team = team
-	}
+		return nil
+	})
 }
 
 func (bot *mensabot) getChannel(channelName string) *model.Channel {
-	rChan, resp := bot.client.GetChannelByName(channelName, bot.team.Id, "")
-	if resp.Error != nil {
-		println("We failed to get the channel: " + channelName)
-		printError(resp.Error)
-		panic(resp.Error)
-	}
-	println("[bot::getChannel] Got channel with name '" + channelName + "': " + rChan.Id)
-	return rChan
+	var channel *model.Channel
+	retryForever("getChannel:"+channelName, func() error {
+		rChan, resp := bot.client.GetChannelByName(channelName, bot.team.Id, "")
+		if resp.Error != nil {
+			return resp.Error
+		}
+		log.Info().Str("component", "getChannel").Str("channel_id", rChan.Id).Str("channel", channelName).Msg("channel resolved")
+		channel = rChan
+		return nil
+	})
+	return channel
 }
 
 func (bot *mensabot) sendMessage(msg string, channelID string, replyToID string) {
@@ -267,30 +373,74 @@ func (bot *mensabot) sendMessage(msg string, channelID string, replyToID string)
 	post.RootId = replyToID
 
 	if _, resp := bot.client.CreatePost(post); resp.Error != nil {
-		println("We failed to send a message to channel: " + channelID)
-		printError(resp.Error)
+		log.Error().Str("component", "sendMessage").Str("channel_id", channelID).Msg("failed to send message")
+		logAppError("sendMessage", resp.Error)
 	}
 }
 
+// startListening consumes websocket events until the connection drops, then
+// reconnects with exponential backoff (capped at retryMaxDelay) and keeps
+// going - a restarted Mattermost server no longer silently kills the bot.
 func (bot *mensabot) startListening() {
 	bot.sendMessage("_["+CONFIG.DisplayName+"] has **started** running_", bot.channelDebug.Id, "")
-	bot.wsClient.Listen()
 
 	for {
-		select {
-		case event := <-bot.wsClient.EventChannel:
-			bot.handleWebSocketEvent(event)
+		bot.wsClient.Listen()
+		bot.consumeEvents()
+
+		log.Warn().Str("component", "startListening").Msg("websocket disconnected, reconnecting")
+		bot.health.setBackoff(retryBaseDelay)
+		delay := retryBaseDelay
+		for {
+			time.Sleep(delay + jitter(delay))
+			if err := bot.reconnectWebSocket(); err != nil {
+				delay = nextBackoff(delay)
+				bot.health.setBackoff(delay)
+				log.Warn().Str("component", "startListening").Err(err).Dur("retry_in", delay).Msg("reconnect failed")
+				continue
+			}
+			bot.health.setBackoff(0)
+			break
 		}
 	}
 }
 
+// consumeEvents reads from wsClient.EventChannel until it is closed.
+func (bot *mensabot) consumeEvents() {
+	for {
+		event, ok := <-bot.wsClient.EventChannel
+		if !ok {
+			return
+		}
+		bot.health.recordEvent()
+		bot.handleWebSocketEvent(event)
+	}
+}
+
+// reconnectWebSocket re-logs in (in case the auth token was invalidated by
+// the server restart) and opens a fresh websocket client.
+func (bot *mensabot) reconnectWebSocket() error {
+	user, resp := bot.client.Login(CONFIG.UserEmail, CONFIG.UserPassword)
+	if resp.Error != nil {
+		return resp.Error
+	}
+	bot.user = user
+
+	wsClient, err := model.NewWebSocketClient4(CONFIG.MattermostWsURL, bot.client.AuthToken)
+	if err != nil {
+		return err
+	}
+	bot.wsClient = wsClient
+	return nil
+}
+
 func (bot *mensabot) handleWebSocketEvent(event *model.WebSocketEvent) {
 	// Skip empty events to avoid noise (especially at shutdown)
 	if event == nil {
 		return
 	}
 
-	fmt.Printf("[bot::handleWebSocketEvent] Handling event: %v\n", event)
+	log.Debug().Str("component", "handleWebSocketEvent").Str("event", event.Event).Msg("handling event")
 
 	// We only care about new posts
 	if event.Event != model.WEBSOCKET_EVENT_POSTED {
@@ -304,7 +454,7 @@ func (bot *mensabot) handleWebSocketEvent(event *model.WebSocketEvent) {
 			return
 		}
 
-		if strings.HasPrefix(post.Message, CONFIG.MentionName) {
+		if strings.HasPrefix(post.Message, CONFIG.MentionName) || strings.HasPrefix(post.Message, SlashPrefix) {
 			bot.handleCommand(post)
 		} else if event.Broadcast.ChannelId == bot.channelDebug.Id {
 			bot.handleCommand(post)
@@ -339,68 +489,32 @@ func (bot *mensabot) writeLegend(channelID string, replyToID string) {
 	bot.sendMessage(msg, channelID, replyToID)
 }
 
-func (bot *mensabot) writeCommands(channelID string, replyToID string) {
-	bot.sendMessage("TODO: Implement commands..", channelID, replyToID)
-}
-
-func (bot *mensabot) handleCommand(post *model.Post) {
-	println("Handling post: " + post.Message)
-
-	if matched, _ := regexp.MatchString(`(?:^|\W)((a|A)live|(r|R)unning|(u|U)p)(?:$|\W)`, post.Message); matched {
-		// If you see any word matching 'alive'/'running'/'up' then respond with status
-		bot.sendMessage("Yes I'm up and running!", post.ChannelId, post.Id)
-		return
-	} else if matched, _ := regexp.MatchString(`(?:^|\W)((h|H)eute|(t|T)oday)(?:$|\W)`, post.Message); matched {
-		// If you see any word matching 'heute' or 'today' post today's canteen plan
-		dishes := getCanteenPlan(CANTEEN_URL_TODAY)
-		bot.writeDishes(dishes, "**Heute gibt es:**", post.ChannelId, post.Id)
-	} else if matched, _ := regexp.MatchString(`(?:^|\W)((m|M)orgen|(t|T)omorrow)(?:$|\W)`, post.Message); matched {
-		// If you see any word matching 'morgen' or 'tomorrow' post tomorrow's canteen plan
-		dishes := getCanteenPlan(CANTEEN_URL_TOMORROW)
-		bot.writeDishes(dishes, "**Morgen gibt es:**", post.ChannelId, post.Id)
-	} else if matched, _ := regexp.MatchString(`(?:^|\W)((l|L)egend(|e))(?:$|\W)`, post.Message); matched {
-		// If you see any word matching 'lengend' write legend
-		bot.writeLegend(post.ChannelId, post.Id)
-	} else if matched, _ := regexp.MatchString(`(?:^|\W)((c|C)ommmand|(h|H)elp)(?:$|\W)`, post.Message); matched {
-		// If you see any word matching 'command' or 'help' write available commands
-		bot.writeCommands(post.ChannelId, post.Id)
-	} else {
-		// If nothing matched return a generic message
-		bot.sendMessage("What does this even mean?!", post.ChannelId, post.Id)
-	}
-}
-
 func readConfig() {
 	if len(os.Args) < 2 {
-		println("ERROR: MensaBot expects the configuration file as first argument!")
-		os.Exit(1)
+		log.Fatal().Str("component", "readConfig").Msg("expects the configuration file as first argument")
 	}
 
 	cfgFile := os.Args[1]
-	_, err := os.Stat(cfgFile)
-	if err != nil {
-		println("Config file is missing: " + cfgFile)
-		panic(err)
+	if _, err := os.Stat(cfgFile); err != nil {
+		log.Fatal().Str("component", "readConfig").Str("file", cfgFile).Err(err).Msg("config file is missing")
 	}
 	if _, err := toml.DecodeFile(cfgFile, &CONFIG); err != nil {
-		panic(err)
+		log.Fatal().Str("component", "readConfig").Err(err).Msg("failed to decode config file")
 	}
 }
 
 func main() {
+	initLogger("", false)
 	readConfig()
+	initLogger(CONFIG.LogLevel, CONFIG.LogJSON)
 
-	bot := newMensaBotFromConfig(&CONFIG)
+	bot, err := newMensaBotFromConfig(&CONFIG)
+	if err != nil {
+		log.Fatal().Str("component", "main").Err(err).Msg("failed to start bot")
+	}
 	go bot.startListening()
 
 	// Forever block main routine
 	// TODO |2018-01-17|: It works without this, investigate what the best practices are
 	select {}
 }
-
-func printError(err *model.AppError) {
-	println("\tError Details:")
-	println("\t\t" + err.Message)
-	println("\t\t" + err.Id)
-	println("\t\t" + err.DetailedError)
-}
\ No newline at end of file
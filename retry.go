@@ -0,0 +1,43 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+)
+
+const (
+	retryBaseDelay = 1 * time.Second
+	retryMaxDelay  = 60 * time.Second
+)
+
+// retryForever calls fn until it succeeds, waiting an exponentially
+// increasing, jittered delay (capped at retryMaxDelay) between attempts.
+// component identifies the caller in log output. This replaces the panics that
+// used to kill the process on a transient Mattermost hiccup during setup.
+func retryForever(component string, fn func() error) {
+	delay := retryBaseDelay
+	for {
+		if err := fn(); err == nil {
+			return
+		} else {
+			log.Warn().Str("component", component).Err(err).Dur("retry_in", delay).Msg("retrying after failure")
+		}
+		time.Sleep(delay + jitter(delay))
+		delay = nextBackoff(delay)
+	}
+}
+
+func nextBackoff(d time.Duration) time.Duration {
+	d *= 2
+	if d > retryMaxDelay {
+		d = retryMaxDelay
+	}
+	return d
+}
+
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d)/2 + 1))
+}
@@ -0,0 +1,149 @@
+// Package scheduler drives a recurring daily callback at a configured time
+// of day, skipping weekdays and holidays that are not enabled.
+package scheduler
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config describes when the scheduler should fire.
+type Config struct {
+	// PostAt is the time of day to run, as "HH:MM" in Timezone.
+	PostAt string
+	// Weekdays restricts which days the scheduler fires on, e.g.
+	// ["Mon", "Tue", "Wed", "Thu", "Fri"]. Defaults to Monday-Friday when
+	// empty.
+	Weekdays []string
+	// Timezone is an IANA zone name, e.g. "Europe/Berlin". Defaults to UTC
+	// when empty.
+	Timezone string
+	// Holidays lists dates to skip even on an enabled weekday, as
+	// "YYYY-MM-DD", e.g. ["2026-12-25", "2026-01-01"].
+	Holidays []string
+}
+
+// Scheduler runs a callback once a day at a configured time, on a
+// configured set of weekdays.
+type Scheduler struct {
+	hour, minute int
+	loc          *time.Location
+	weekdays     map[time.Weekday]bool
+	holidays     map[string]bool
+	run          func(time.Time)
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// New validates cfg and returns a Scheduler that will call run at the next
+// matching time once Start is called.
+func New(cfg Config, run func(time.Time)) (*Scheduler, error) {
+	hour, minute, err := parseTimeOfDay(cfg.PostAt)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := time.UTC
+	if cfg.Timezone != "" {
+		l, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid timezone %q: %w", cfg.Timezone, err)
+		}
+		loc = l
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	if len(cfg.Weekdays) == 0 {
+		for _, d := range []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday} {
+			weekdays[d] = true
+		}
+	} else {
+		for _, name := range cfg.Weekdays {
+			d, ok := parseWeekday(name)
+			if !ok {
+				return nil, fmt.Errorf("scheduler: unknown weekday %q", name)
+			}
+			weekdays[d] = true
+		}
+	}
+
+	holidays := make(map[string]bool, len(cfg.Holidays))
+	for _, d := range cfg.Holidays {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return nil, fmt.Errorf("scheduler: invalid holiday %q, want \"YYYY-MM-DD\": %w", d, err)
+		}
+		holidays[d] = true
+	}
+
+	return &Scheduler{hour: hour, minute: minute, loc: loc, weekdays: weekdays, holidays: holidays, run: run}, nil
+}
+
+// Start runs the scheduler loop in its own goroutine. It never stops; the
+// process exiting is what ends it, matching the rest of the bot's
+// goroutines (startListening, runPruneLoop).
+func (s *Scheduler) Start() {
+	go s.loop()
+}
+
+func (s *Scheduler) loop() {
+	for {
+		next := s.computeNext(time.Now().In(s.loc))
+
+		s.mu.Lock()
+		s.next = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		now := <-timer.C
+		s.run(now)
+	}
+}
+
+// computeNext returns the next configured PostAt on an enabled weekday that
+// is not a configured holiday, strictly after from.
+func (s *Scheduler) computeNext(from time.Time) time.Time {
+	candidate := time.Date(from.Year(), from.Month(), from.Day(), s.hour, s.minute, 0, 0, s.loc)
+	if !candidate.After(from) {
+		candidate = candidate.AddDate(0, 0, 1)
+	}
+	for !s.weekdays[candidate.Weekday()] || s.holidays[candidate.Format("2006-01-02")] {
+		candidate = candidate.AddDate(0, 0, 1)
+	}
+	return candidate
+}
+
+// NextRun returns the next time the scheduler is due to fire. It is the
+// zero time until Start has computed the first run.
+func (s *Scheduler) NextRun() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next
+}
+
+func parseTimeOfDay(s string) (hour, minute int, err error) {
+	if _, err := fmt.Sscanf(s, "%d:%d", &hour, &minute); err != nil {
+		return 0, 0, fmt.Errorf("scheduler: invalid PostAt %q, want \"HH:MM\": %w", s, err)
+	}
+	if hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("scheduler: PostAt %q out of range", s)
+	}
+	return hour, minute, nil
+}
+
+var weekdayAliases = map[string]time.Weekday{
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+	"sun": time.Sunday, "sunday": time.Sunday,
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	d, ok := weekdayAliases[strings.ToLower(s)]
+	return d, ok
+}
@@ -0,0 +1,81 @@
+package main
+
+import "time"
+
+// postDailyPlans is the scheduler callback: it posts today's plan into
+// every canteen's configured Channel, then DMs every subscriber their
+// filtered plan. Scraping/cache errors for one canteen don't stop the rest.
+func (bot *mensabot) postDailyPlans(now time.Time) {
+	var allDishes []dish
+
+	for _, c := range bot.canteens {
+		dishes, err := bot.fetchCanteenPlan(c, 0)
+		if err != nil {
+			log.Warn().Str("component", "postDailyPlans").Str("canteen_id", c.ID).Err(err).Msg("failed to fetch plan")
+			continue
+		}
+		allDishes = append(allDishes, dishes...)
+
+		channel, ok := bot.canteenChannel[c.Name]
+		if !ok {
+			continue
+		}
+		bot.writeDishes(dishes, "**Heute gibt es ("+c.Name+"):**", channel.Id, "")
+	}
+
+	bot.postSubscriptions(allDishes)
+}
+
+// postSubscriptions DMs every subscriber the dishes matching their filter,
+// skipping them entirely if nothing matches.
+func (bot *mensabot) postSubscriptions(allDishes []dish) {
+	if bot.store == nil {
+		return
+	}
+
+	subs, err := bot.store.ListSubscriptions()
+	if err != nil {
+		log.Warn().Str("component", "postSubscriptions").Err(err).Msg("failed to list subscriptions")
+		return
+	}
+
+	for _, sub := range subs {
+		filtered := filterDishes(allDishes, sub.Filter)
+		if len(filtered) == 0 {
+			continue
+		}
+
+		dmChannel, resp := bot.client.CreateDirectChannel(bot.user.Id, sub.UserID)
+		if resp.Error != nil {
+			log.Warn().Str("component", "postSubscriptions").Str("user_id", sub.UserID).Msg("failed to open DM")
+			logAppError("postSubscriptions", resp.Error)
+			continue
+		}
+
+		bot.writeDishes(filtered, "**Dein Speiseplan heute:**", dmChannel.Id, "")
+	}
+}
+
+// filterDishes returns the subset of dishes matching filter. An unknown
+// filter (including "" / "all") returns dishes unfiltered.
+func filterDishes(dishes []dish, filter string) []dish {
+	var out []dish
+	for _, d := range dishes {
+		switch filter {
+		case "vegan":
+			if !d.isVegan {
+				continue
+			}
+		case "vegetarian":
+			if !d.isVegetarian {
+				continue
+			}
+		case "favorites":
+			if !d.isFavorite() {
+				continue
+			}
+		}
+		out = append(out, d)
+	}
+	return out
+}
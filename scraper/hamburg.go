@@ -0,0 +1,107 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/yhat/scrape"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// HamburgScraperName is the registry key for HamburgScraper.
+const HamburgScraperName = "studierendenwerk-hamburg"
+
+const hamburgURLTemplate = "http://speiseplan.studierendenwerk-hamburg.de/de/%s/2018/%s/"
+
+func init() {
+	Register(HamburgScraperName, HamburgScraper{})
+}
+
+// HamburgScraper scrapes canteen plans from the Studierendenwerk Hamburg
+// website (speiseplan.studierendenwerk-hamburg.de). The site only exposes
+// today's and tomorrow's plan, so offsets other than 0 and 1 are rejected.
+type HamburgScraper struct{}
+
+func (HamburgScraper) FetchDay(canteenID string, offset int) ([]Dish, error) {
+	var dayCode string
+	switch offset {
+	case 0:
+		dayCode = "0"
+	case 1:
+		dayCode = "99"
+	default:
+		return nil, fmt.Errorf("%s: only offset 0 (today) and 1 (tomorrow) are supported, got %d", HamburgScraperName, offset)
+	}
+
+	url := fmt.Sprintf(hamburgURLTemplate, canteenID, dayCode)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	root, err := html.Parse(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var dishes []Dish
+	for _, dn := range scrape.FindAll(root, scrape.ByClass("dish-description")) {
+		dishes = append(dishes, dishFromNode(dn))
+	}
+
+	return dishes, nil
+}
+
+func trimNodeName(name string) (trimmed string) {
+	trimmed = strings.Trim(name, " \t\n")
+	trimmed = strings.Replace(trimmed, "( ", "(", -1)
+	trimmed = strings.Replace(trimmed, " )", ")", -1)
+	trimmed = strings.Replace(trimmed, " ,", ",", -1)
+	trimmed = strings.Replace(trimmed, "  ", " ", -1)
+
+	return
+}
+
+func dishFromNode(node *html.Node) Dish {
+	name := trimNodeName(scrape.Text(node))
+
+	var prices [3]string
+	var isVegetarian bool
+	var isVegan bool
+	var containsBeef bool
+	var containsPork bool
+	var containsFish bool
+	var containsChicken bool
+	var lactoseFree bool
+
+	priceNodes := scrape.FindAll(node.Parent, scrape.ByClass("price"))
+	imgNodes := scrape.FindAll(node, scrape.ByTag(atom.Img))
+
+	for i, price := range priceNodes {
+		prices[i] = strings.Replace(scrape.Text(price), "\xc2\xa0", "", -1)
+	}
+
+	for _, img := range imgNodes {
+		switch strings.ToLower(scrape.Attr(img, "title")) {
+		case "vegetarisch":
+			isVegetarian = true
+		case "vegan":
+			isVegan = true
+		case "mit rind":
+			containsBeef = true
+		case "mit schwein":
+			containsPork = true
+		case "mit fisch":
+			containsFish = true
+		case "mit geflügel":
+			containsChicken = true
+		case "laktosefrei":
+			lactoseFree = true
+		}
+	}
+
+	return Dish{name, prices, isVegetarian || isVegan, isVegan, containsBeef, containsPork, containsFish, containsChicken, lactoseFree}
+}
@@ -0,0 +1,45 @@
+// Package scraper fetches canteen plans from whatever source a canteen is
+// configured to use. Adding support for a new site is a matter of
+// implementing Scraper and registering it under a name, see hamburg.go.
+package scraper
+
+import "fmt"
+
+// Dish is a single dish as fetched from a canteen's menu, before it is
+// cached or rendered.
+type Dish struct {
+	Name            string
+	Prices          [3]string
+	IsVegetarian    bool
+	IsVegan         bool
+	ContainsBeef    bool
+	ContainsPork    bool
+	ContainsFish    bool
+	ContainsChicken bool
+	LactoseFree     bool
+}
+
+// Scraper fetches the plan of a single canteen for a single day.
+type Scraper interface {
+	// FetchDay returns the dishes for canteenID on the day offset days from
+	// today (0 = today, 1 = tomorrow, ...). A Scraper may return an error if
+	// it does not support the requested offset.
+	FetchDay(canteenID string, offset int) ([]Dish, error)
+}
+
+var registry = make(map[string]Scraper)
+
+// Register adds a Scraper to the registry under name. It is meant to be
+// called from the init() function of the package implementing it.
+func Register(name string, s Scraper) {
+	registry[name] = s
+}
+
+// Get returns the Scraper registered under name, or an error if none is.
+func Get(name string) (Scraper, error) {
+	s, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("scraper: no scraper registered under name %q", name)
+	}
+	return s, nil
+}
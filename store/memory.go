@@ -0,0 +1,124 @@
+package store
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	dishes    []Dish
+	fetchedAt time.Time
+}
+
+// MemoryStore is an in-memory Store implementation, used to swap out the
+// SQLite backend in tests without touching the filesystem.
+type MemoryStore struct {
+	mu            sync.Mutex
+	entries       map[string]map[string]memoryEntry // canteenID -> date key -> entry
+	subscriptions map[string]string                 // userID -> filter
+}
+
+// NewMemoryStore returns an empty, ready to use MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries:       make(map[string]map[string]memoryEntry),
+		subscriptions: make(map[string]string),
+	}
+}
+
+func (m *MemoryStore) SavePlan(canteenID string, date time.Time, dishes []Dish) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.entries[canteenID] == nil {
+		m.entries[canteenID] = make(map[string]memoryEntry)
+	}
+	cp := make([]Dish, len(dishes))
+	copy(cp, dishes)
+	m.entries[canteenID][dateKey(date)] = memoryEntry{dishes: cp, fetchedAt: time.Now()}
+	return nil
+}
+
+func (m *MemoryStore) GetPlan(canteenID string, date time.Time) ([]Dish, time.Time, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[canteenID][dateKey(date)]
+	if !ok {
+		return nil, time.Time{}, false, nil
+	}
+	return entry.dishes, entry.fetchedAt, true, nil
+}
+
+func (m *MemoryStore) SearchDish(query string) ([]Occurrence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	query = strings.ToLower(query)
+	var occurrences []Occurrence
+	for canteenID, byDate := range m.entries {
+		for dateStr, entry := range byDate {
+			date, err := time.Parse("2006-01-02", dateStr)
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range entry.dishes {
+				if strings.Contains(strings.ToLower(d.Name), query) {
+					occurrences = append(occurrences, Occurrence{CanteenID: canteenID, Date: date, Dish: d})
+				}
+			}
+		}
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool { return occurrences[i].Date.After(occurrences[j].Date) })
+	return occurrences, nil
+}
+
+func (m *MemoryStore) Prune(olderThan time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := dateKey(olderThan)
+	for canteenID, byDate := range m.entries {
+		for dateStr := range byDate {
+			if dateStr < cutoff {
+				delete(byDate, dateStr)
+			}
+		}
+		if len(byDate) == 0 {
+			delete(m.entries, canteenID)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) SaveSubscription(userID string, filter string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscriptions[userID] = filter
+	return nil
+}
+
+func (m *MemoryStore) RemoveSubscription(userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.subscriptions, userID)
+	return nil
+}
+
+func (m *MemoryStore) ListSubscriptions() ([]Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subs := make([]Subscription, 0, len(m.subscriptions))
+	for userID, filter := range m.subscriptions {
+		subs = append(subs, Subscription{UserID: userID, Filter: filter})
+	}
+	return subs, nil
+}
+
+func (m *MemoryStore) Close() error { return nil }
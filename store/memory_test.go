@@ -0,0 +1,116 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreSaveAndGetPlan(t *testing.T) {
+	m := NewMemoryStore()
+	date := time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC)
+	dishes := []Dish{{Name: "Currywurst"}, {Name: "Salat"}}
+
+	if err := m.SavePlan("580", date, dishes); err != nil {
+		t.Fatalf("SavePlan: %v", err)
+	}
+
+	got, fetchedAt, ok, err := m.GetPlan("580", date)
+	if err != nil {
+		t.Fatalf("GetPlan: %v", err)
+	}
+	if !ok {
+		t.Fatal("GetPlan: want ok=true for a saved plan")
+	}
+	if len(got) != 2 || got[0].Name != "Currywurst" {
+		t.Fatalf("GetPlan: got %+v, want %+v", got, dishes)
+	}
+	if time.Since(fetchedAt) > time.Second {
+		t.Fatalf("GetPlan: fetchedAt %v is not recent", fetchedAt)
+	}
+
+	if _, _, ok, err := m.GetPlan("580", date.AddDate(0, 0, 1)); err != nil || ok {
+		t.Fatalf("GetPlan for unsaved date: ok=%v err=%v, want ok=false", ok, err)
+	}
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	m := NewMemoryStore()
+	old := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC)
+
+	if err := m.SavePlan("580", old, []Dish{{Name: "Alt"}}); err != nil {
+		t.Fatalf("SavePlan: %v", err)
+	}
+	if err := m.SavePlan("580", recent, []Dish{{Name: "Neu"}}); err != nil {
+		t.Fatalf("SavePlan: %v", err)
+	}
+
+	if err := m.Prune(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	if _, _, ok, _ := m.GetPlan("580", old); ok {
+		t.Fatal("Prune: old entry should have been deleted")
+	}
+	if _, _, ok, _ := m.GetPlan("580", recent); !ok {
+		t.Fatal("Prune: recent entry should have survived")
+	}
+}
+
+func TestMemoryStoreSearchDish(t *testing.T) {
+	m := NewMemoryStore()
+	day1 := time.Date(2026, 7, 20, 0, 0, 0, 0, time.UTC)
+	day2 := time.Date(2026, 7, 26, 0, 0, 0, 0, time.UTC)
+
+	if err := m.SavePlan("580", day1, []Dish{{Name: "Currywurst"}}); err != nil {
+		t.Fatalf("SavePlan: %v", err)
+	}
+	if err := m.SavePlan("580", day2, []Dish{{Name: "CURRYWURST spezial"}}); err != nil {
+		t.Fatalf("SavePlan: %v", err)
+	}
+
+	occurrences, err := m.SearchDish("currywurst")
+	if err != nil {
+		t.Fatalf("SearchDish: %v", err)
+	}
+	if len(occurrences) != 2 {
+		t.Fatalf("SearchDish: got %d occurrences, want 2", len(occurrences))
+	}
+	if !occurrences[0].Date.After(occurrences[1].Date) {
+		t.Fatalf("SearchDish: occurrences not sorted newest first: %+v", occurrences)
+	}
+
+	if occurrences, err := m.SearchDish("nudelsalat"); err != nil || len(occurrences) != 0 {
+		t.Fatalf("SearchDish for absent dish: got %d occurrences, err=%v", len(occurrences), err)
+	}
+}
+
+func TestMemoryStoreSubscriptions(t *testing.T) {
+	m := NewMemoryStore()
+
+	if err := m.SaveSubscription("u1", "vegan"); err != nil {
+		t.Fatalf("SaveSubscription: %v", err)
+	}
+	if err := m.SaveSubscription("u2", "all"); err != nil {
+		t.Fatalf("SaveSubscription: %v", err)
+	}
+
+	subs, err := m.ListSubscriptions()
+	if err != nil {
+		t.Fatalf("ListSubscriptions: %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("ListSubscriptions: got %d, want 2", len(subs))
+	}
+
+	if err := m.RemoveSubscription("u1"); err != nil {
+		t.Fatalf("RemoveSubscription: %v", err)
+	}
+	subs, err = m.ListSubscriptions()
+	if err != nil {
+		t.Fatalf("ListSubscriptions: %v", err)
+	}
+	if len(subs) != 1 || subs[0].UserID != "u2" {
+		t.Fatalf("ListSubscriptions after removal: got %+v, want only u2", subs)
+	}
+}
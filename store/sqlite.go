@@ -0,0 +1,222 @@
+package store
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// schema is applied once on open; CREATE TABLE/INDEX IF NOT EXISTS makes it
+// safe to run against an existing database, so it doubles as our migration.
+const schema = `
+CREATE TABLE IF NOT EXISTS dishes (
+	canteen_id        TEXT NOT NULL,
+	date              TEXT NOT NULL,
+	name              TEXT NOT NULL,
+	price1            TEXT NOT NULL,
+	price2            TEXT NOT NULL,
+	price3            TEXT NOT NULL,
+	is_vegetarian     INTEGER NOT NULL,
+	is_vegan          INTEGER NOT NULL,
+	contains_beef     INTEGER NOT NULL,
+	contains_pork     INTEGER NOT NULL,
+	contains_fish     INTEGER NOT NULL,
+	contains_chicken  INTEGER NOT NULL,
+	lactose_free      INTEGER NOT NULL,
+	fetched_at        INTEGER NOT NULL,
+	PRIMARY KEY (canteen_id, date, name)
+);
+CREATE INDEX IF NOT EXISTS idx_dishes_name ON dishes(name);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+	user_id TEXT NOT NULL PRIMARY KEY,
+	filter  TEXT NOT NULL
+);
+`
+
+// SQLiteStore is the production Store backed by a go-sqlite3 database file.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore opens (creating if necessary) the sqlite database at path
+// and applies the schema.
+func NewSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite only allows one writer at a time; with the default pool, the
+	// prune loop, the scheduler and a command handler writing concurrently
+	// would intermittently fail with "database is locked".
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) SavePlan(canteenID string, date time.Time, dishes []Dish) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	key := dateKey(date)
+	if _, err := tx.Exec(`DELETE FROM dishes WHERE canteen_id = ? AND date = ?`, canteenID, key); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	fetchedAt := time.Now().Unix()
+	stmt, err := tx.Prepare(`INSERT INTO dishes (
+		canteen_id, date, name, price1, price2, price3,
+		is_vegetarian, is_vegan, contains_beef, contains_pork,
+		contains_fish, contains_chicken, lactose_free, fetched_at
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, d := range dishes {
+		if _, err := stmt.Exec(
+			canteenID, key, d.Name, d.Prices[0], d.Prices[1], d.Prices[2],
+			boolToInt(d.IsVegetarian), boolToInt(d.IsVegan), boolToInt(d.ContainsBeef), boolToInt(d.ContainsPork),
+			boolToInt(d.ContainsFish), boolToInt(d.ContainsChicken), boolToInt(d.LactoseFree), fetchedAt,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLiteStore) GetPlan(canteenID string, date time.Time) ([]Dish, time.Time, bool, error) {
+	rows, err := s.db.Query(`SELECT name, price1, price2, price3,
+		is_vegetarian, is_vegan, contains_beef, contains_pork,
+		contains_fish, contains_chicken, lactose_free, fetched_at
+		FROM dishes WHERE canteen_id = ? AND date = ?`, canteenID, dateKey(date))
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	defer rows.Close()
+
+	var dishes []Dish
+	var fetchedAt time.Time
+	for rows.Next() {
+		var d Dish
+		var isVeg, isVegan, beef, pork, fish, chicken, lactoseFree int
+		var fetchedUnix int64
+		if err := rows.Scan(&d.Name, &d.Prices[0], &d.Prices[1], &d.Prices[2],
+			&isVeg, &isVegan, &beef, &pork, &fish, &chicken, &lactoseFree, &fetchedUnix); err != nil {
+			return nil, time.Time{}, false, err
+		}
+		d.IsVegetarian = isVeg != 0
+		d.IsVegan = isVegan != 0
+		d.ContainsBeef = beef != 0
+		d.ContainsPork = pork != 0
+		d.ContainsFish = fish != 0
+		d.ContainsChicken = chicken != 0
+		d.LactoseFree = lactoseFree != 0
+		fetchedAt = time.Unix(fetchedUnix, 0)
+		dishes = append(dishes, d)
+	}
+
+	return dishes, fetchedAt, len(dishes) > 0, rows.Err()
+}
+
+func (s *SQLiteStore) SearchDish(query string) ([]Occurrence, error) {
+	rows, err := s.db.Query(`SELECT canteen_id, date, name, price1, price2, price3,
+		is_vegetarian, is_vegan, contains_beef, contains_pork,
+		contains_fish, contains_chicken, lactose_free
+		FROM dishes WHERE LOWER(name) LIKE ? ESCAPE '\' ORDER BY date DESC`, "%"+escapeLike(strings.ToLower(query))+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var occurrences []Occurrence
+	for rows.Next() {
+		var o Occurrence
+		var dateStr string
+		var isVeg, isVegan, beef, pork, fish, chicken, lactoseFree int
+		if err := rows.Scan(&o.CanteenID, &dateStr, &o.Dish.Name, &o.Dish.Prices[0], &o.Dish.Prices[1], &o.Dish.Prices[2],
+			&isVeg, &isVegan, &beef, &pork, &fish, &chicken, &lactoseFree); err != nil {
+			return nil, err
+		}
+		o.Dish.IsVegetarian = isVeg != 0
+		o.Dish.IsVegan = isVegan != 0
+		o.Dish.ContainsBeef = beef != 0
+		o.Dish.ContainsPork = pork != 0
+		o.Dish.ContainsFish = fish != 0
+		o.Dish.ContainsChicken = chicken != 0
+		o.Dish.LactoseFree = lactoseFree != 0
+		o.Date, err = time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, o)
+	}
+
+	return occurrences, rows.Err()
+}
+
+func (s *SQLiteStore) Prune(olderThan time.Time) error {
+	_, err := s.db.Exec(`DELETE FROM dishes WHERE date < ?`, dateKey(olderThan))
+	return err
+}
+
+func (s *SQLiteStore) SaveSubscription(userID string, filter string) error {
+	_, err := s.db.Exec(`INSERT INTO subscriptions (user_id, filter) VALUES (?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET filter = excluded.filter`, userID, filter)
+	return err
+}
+
+func (s *SQLiteStore) RemoveSubscription(userID string) error {
+	_, err := s.db.Exec(`DELETE FROM subscriptions WHERE user_id = ?`, userID)
+	return err
+}
+
+func (s *SQLiteStore) ListSubscriptions() ([]Subscription, error) {
+	rows, err := s.db.Query(`SELECT user_id, filter FROM subscriptions`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		var sub Subscription
+		if err := rows.Scan(&sub.UserID, &sub.Filter); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// escapeLike escapes the LIKE wildcard characters '%', '_' and the escape
+// character '\' itself, so a query containing them matches literally instead
+// of acting as a wildcard.
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
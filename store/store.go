@@ -0,0 +1,66 @@
+// Package store persists scraped canteen plans so the bot can answer
+// without hitting the Studierendenwerk website on every request, and so
+// past plans stay queryable (week view, "last friday", dish history).
+package store
+
+import (
+	"time"
+
+	"github.com/wolfposd/mensabot/scraper"
+)
+
+// Dish is the persisted form of a canteen dish - the same shape the
+// scraper package produces, so plans can be stored without conversion.
+type Dish = scraper.Dish
+
+// Occurrence is a single historical sighting of a dish, used by SearchDish.
+type Occurrence struct {
+	CanteenID string
+	Date      time.Time
+	Dish      Dish
+}
+
+// Subscription is a user's standing request for a filtered daily DM, set up
+// via the "subscribe"/"unsubscribe" commands.
+type Subscription struct {
+	UserID string
+	Filter string
+}
+
+// Store is the persistence interface used by the bot. It is implemented by
+// SQLiteStore for production use and by MemoryStore for tests.
+type Store interface {
+	// SavePlan replaces the stored dishes for canteenID/date with dishes,
+	// stamping them with the current time.
+	SavePlan(canteenID string, date time.Time, dishes []Dish) error
+
+	// GetPlan returns the stored dishes for canteenID/date, the time they
+	// were fetched, and whether an entry exists at all. SQLiteStore keeps no
+	// entry for a plan saved with no dishes, so it reports ok=false for one.
+	GetPlan(canteenID string, date time.Time) (dishes []Dish, fetchedAt time.Time, ok bool, err error)
+
+	// SearchDish returns every stored occurrence whose name contains query
+	// (case-insensitive), newest first. SQLiteStore folds case for ASCII
+	// letters only, so e.g. "Ü" and "ü" do not match each other there.
+	SearchDish(query string) ([]Occurrence, error)
+
+	// Prune deletes plans older than olderThan.
+	Prune(olderThan time.Time) error
+
+	// SaveSubscription replaces userID's subscription with filter,
+	// creating it if it doesn't exist yet.
+	SaveSubscription(userID string, filter string) error
+
+	// RemoveSubscription deletes userID's subscription, if any.
+	RemoveSubscription(userID string) error
+
+	// ListSubscriptions returns every active subscription.
+	ListSubscriptions() ([]Subscription, error)
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+func dateKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}